@@ -3,9 +3,7 @@ package structs
 import (
 	"fmt"
 	"math/big"
-	"strconv"
 	"strings"
-	"unicode"
 
 	"github.com/hashicorp/nomad/helper"
 )
@@ -42,15 +40,29 @@ type Unit struct {
 	// InverseMultiplier specifies that the multiplier is an inverse so:
 	// Base / Multiplier. For example a mW is a W/1000.
 	InverseMultiplier bool
+
+	// Dim is the unit's physical dimension, expressed as exponents of the
+	// base dimensions (Byte, Time, Energy, ...). Units built up through
+	// RegisterUnit or composite parsing (MB/s, core·GHz) set this directly;
+	// units that only set Base fall back to dimension() deriving it from
+	// Base, so existing callers are unaffected.
+	Dim Dimension
+
+	// Prefix is the unit's exact scale factor relative to the SI base of its
+	// Dimension (e.g. 1/1000 for mW, 1073741824 for GiB). When nil it is
+	// derived from Multiplier/InverseMultiplier.
+	Prefix *big.Rat
 }
 
-// Comparable returns if two units are comparable
+// Comparable returns if two units are comparable, meaning they share the
+// same Dimension regardless of prefix (GiB/s and MB/s are both comparable
+// because they're both a byte-rate).
 func (u *Unit) Comparable(o *Unit) bool {
 	if u == nil || o == nil {
 		return false
 	}
 
-	return u.Base == o.Base
+	return u.dimension().Equal(o.dimension())
 }
 
 // Attribute is used to describe the value of an attribute, optionally
@@ -120,8 +132,8 @@ func (a *Attribute) GoString() string {
 // Validate checks if the attribute is valid
 func (a *Attribute) Validate() error {
 	if a.Unit != "" {
-		if _, ok := UnitIndex[a.Unit]; !ok {
-			return fmt.Errorf("unrecognized unit %q", a.Unit)
+		if _, err := parseUnit(a.Unit); err != nil {
+			return fmt.Errorf("unrecognized unit %q: %v", a.Unit, err)
 		}
 
 		// Check only int/float set
@@ -167,6 +179,11 @@ func (a *Attribute) Compare(b *Attribute) (int, bool) {
 	return a.comparitor()(b)
 }
 
+// isNumeric returns whether the attribute holds an Int or Float value.
+func (a *Attribute) isNumeric() bool {
+	return a != nil && (a.Int != nil || a.Float != nil)
+}
+
 // comparitor returns the comparitor function for the attribute
 func (a *Attribute) comparitor() compareFn {
 	if a.Bool != nil {
@@ -199,9 +216,13 @@ func (a *Attribute) stringComparitor(b *Attribute) (int, bool) {
 // numberComparitor compares two number attributes, having either Int or Float
 // set.
 func (a *Attribute) numberComparitor(b *Attribute) (int, bool) {
-	// If they are both integers we do perfect precision comparisons
-	if a.Int != nil && b.Int != nil {
-		return a.intComparitor(b)
+	// Prefer exact rational arithmetic whenever both sides can produce one.
+	// big.Float's fixed precision can otherwise report a spurious inequality
+	// for values with a fractional multiplier, e.g. 1000mW compared to 1W.
+	if ar, aok := a.getBigRat(); aok {
+		if br, bok := b.getBigRat(); bok {
+			return ar.Cmp(br), true
+		}
 	}
 
 	// Push both into the float space
@@ -214,20 +235,6 @@ func (a *Attribute) numberComparitor(b *Attribute) (int, bool) {
 	return af.Cmp(bf), true
 }
 
-// intComparitor compares two integer attributes.
-func (a *Attribute) intComparitor(b *Attribute) (int, bool) {
-	ai := a.getInt()
-	bi := b.getInt()
-
-	if ai == bi {
-		return 0, true
-	} else if ai < bi {
-		return -1, true
-	} else {
-		return 1, true
-	}
-}
-
 // nullComparitor always returns false and is used when no comparison function
 // is possible
 func nullComparitor(*Attribute) (int, bool) {
@@ -259,44 +266,34 @@ func (a *Attribute) getBigFloat() *big.Float {
 		return f
 	}
 
-	// Convert to the base unit
-	multiplier := new(big.Float)
-	multiplier.SetPrec(floatPrecision)
-	multiplier.SetInt64(u.Multiplier)
-	if u.InverseMultiplier {
-		base := big.NewFloat(1.0)
-		base.SetPrec(floatPrecision)
-		multiplier = multiplier.Quo(base, multiplier)
-	}
-
-	f.Mul(f, multiplier)
+	// Convert to the base unit. u.scale() may be a fractional big.Rat (e.g.
+	// 1/1000 for mW or a composite resolved by parseUnit such as mW*s), so go
+	// through Rat rather than reconstructing the multiplier by hand.
+	scale := new(big.Float).SetPrec(floatPrecision).SetRat(u.scale())
+	f.Mul(f, scale)
 	return f
 }
 
-// getInt returns an int representation of the attribute, converting
-// the value to the base unit if a unit is specified.
-func (a *Attribute) getInt() int64 {
+// getBigRat returns an exact big.Rat representation of the attribute when
+// possible. Only Int-valued attributes qualify: their unit scale, whether an
+// integer Multiplier or a fractional Prefix (InverseMultiplier units like mW,
+// or a composite unit such as mW*s), is itself always rational, so the
+// product is exact. Float-valued attributes report false and fall back to
+// the big.Float path, since the precision loss already happened when the
+// literal was parsed.
+func (a *Attribute) getBigRat() (*big.Rat, bool) {
 	if a.Int == nil {
-		return 0
+		return nil, false
 	}
 
-	i := *a.Int
+	r := new(big.Rat).SetInt64(*a.Int)
 
-	// Get the unit
 	u := a.getTypedUnit()
-
-	// If there is no unit just return the int
 	if u == nil {
-		return i
-	}
-
-	if u.InverseMultiplier {
-		i /= u.Multiplier
-	} else {
-		i *= u.Multiplier
+		return r, true
 	}
 
-	return i
+	return r.Mul(r, u.scale()), true
 }
 
 // Comparable returns whether they are comparable
@@ -305,15 +302,20 @@ func (a *Attribute) Comparable(b *Attribute) bool {
 		return false
 	}
 
-	// First use the units to decide if comparison is possible
+	// First use the units to decide if comparison is possible. Validate
+	// only allows a Unit on an Int/Float attribute, so a unit on one side
+	// with none on the other is still comparable when that unit is itself
+	// dimensionless (e.g. "%"): a dimensionless value is numerically just a
+	// scaled number, the same way getBigFloat/getBigRat treat a nil unit as
+	// scale-1 and dimensionless.
 	aUnit := a.getTypedUnit()
 	bUnit := b.getTypedUnit()
 	if aUnit != nil && bUnit != nil {
 		return aUnit.Comparable(bUnit)
 	} else if aUnit != nil && bUnit == nil {
-		return false
+		return len(aUnit.dimension()) == 0 && b.isNumeric()
 	} else if aUnit == nil && bUnit != nil {
-		return false
+		return len(bUnit.dimension()) == 0 && a.isNumeric()
 	}
 
 	if a.String != nil {
@@ -332,69 +334,22 @@ func (a *Attribute) Comparable(b *Attribute) bool {
 	return true
 }
 
-// getTypedUnit returns the Unit for the attribute or nil if no unit exists.
+// getTypedUnit returns the Unit for the attribute or nil if no unit is set or
+// the unit string can't be resolved. Composite units such as "GiB/s" or
+// "core·GHz" are decomposed and resolved against the unit registry; see
+// parseUnit. Comparable and mulDiv/addSub rely on nil meaning "no unit" to
+// fall through to their own type checks, so this must not return
+// parseUnit("")'s dimensionless Unit for an empty a.Unit.
 func (a *Attribute) getTypedUnit() *Unit {
-	return UnitIndex[a.Unit]
-}
-
-// ParseAttribute takes a string and parses it into an attribute, pulling out
-// units if they are specified as a suffix on a number
-func ParseAttribute(input string) *Attribute {
-	ll := len(input)
-	if ll == 0 {
-		return &Attribute{String: helper.StringToPtr(input)}
-	}
-
-	// Try to parse as a bool
-	b, err := strconv.ParseBool(input)
-	if err == nil {
-		return &Attribute{Bool: helper.BoolToPtr(b)}
-	}
-
-	// Check if the string is a number ending with potential units
-	if unicode.IsLetter(rune(input[ll-1])) {
-		// Try suffix matching
-		var unit string
-		for _, u := range lengthSortedUnits {
-			if strings.HasSuffix(input, u) {
-				unit = u
-				break
-			}
-		}
-
-		// Check if we know about the unit. If we don't we can only treat this
-		// as a string
-		if len(unit) == 0 {
-			return &Attribute{String: helper.StringToPtr(input)}
-		}
-
-		// Grab the numeric
-		numeric := strings.TrimSpace(strings.TrimSuffix(input, unit))
-
-		// Try to parse as an int
-		i, err := strconv.ParseInt(numeric, 10, 64)
-		if err == nil {
-			return &Attribute{Int: helper.Int64ToPtr(i), Unit: unit}
-		}
-
-		// Try to parse as a float
-		f, err := strconv.ParseFloat(numeric, 64)
-		if err == nil {
-			return &Attribute{Float: helper.Float64ToPtr(f), Unit: unit}
-		}
-	}
-
-	// Try to parse as an int
-	i, err := strconv.ParseInt(input, 10, 64)
-	if err == nil {
-		return &Attribute{Int: helper.Int64ToPtr(i)}
+	if a.Unit == "" {
+		return nil
 	}
 
-	// Try to parse as a float
-	f, err := strconv.ParseFloat(input, 64)
-	if err == nil {
-		return &Attribute{Float: helper.Float64ToPtr(f)}
+	u, err := parseUnit(a.Unit)
+	if err != nil {
+		return nil
 	}
 
-	return &Attribute{String: helper.StringToPtr(input)}
+	return u
 }
+
@@ -0,0 +1,284 @@
+package structs
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/hashicorp/nomad/helper"
+)
+
+// Add returns a new Attribute holding a+b, expressed in whichever of the two
+// units is coarser (has the larger scale), e.g. 1GiB + 512MiB is rendered in
+// GiB, as 1.5GiB. a and b must both be numeric and share the same Dimension.
+func (a *Attribute) Add(b *Attribute) (*Attribute, error) {
+	return a.addSub(b, false)
+}
+
+// Sub returns a new Attribute holding a-b, expressed in whichever of the two
+// units is coarser. a and b must both be numeric and share the same
+// Dimension.
+func (a *Attribute) Sub(b *Attribute) (*Attribute, error) {
+	return a.addSub(b, true)
+}
+
+func (a *Attribute) addSub(b *Attribute, negate bool) (*Attribute, error) {
+	if err := a.checkNumeric(b); err != nil {
+		return nil, err
+	}
+	if !a.Comparable(b) {
+		return nil, fmt.Errorf("attributes have incompatible units: %q and %q", a.Unit, b.Unit)
+	}
+
+	resultUnit := coarserUnit(a.Unit, b.Unit)
+	preferInt := a.Int != nil && b.Int != nil
+
+	if ar, aok := a.getBigRat(); aok {
+		if br, bok := b.getBigRat(); bok {
+			if negate {
+				br = new(big.Rat).Neg(br)
+			}
+			return newAttributeFromBaseRat(new(big.Rat).Add(ar, br), resultUnit, preferInt), nil
+		}
+	}
+
+	af, bf := a.getBigFloat(), b.getBigFloat()
+	if af == nil || bf == nil {
+		return nil, fmt.Errorf("attributes have no comparable numeric value")
+	}
+	if negate {
+		bf = new(big.Float).SetPrec(floatPrecision).Neg(bf)
+	}
+	sum := new(big.Float).SetPrec(floatPrecision).Add(af, bf)
+	return newAttributeFromBaseFloat(sum, resultUnit), nil
+}
+
+// Mul returns a new Attribute holding a*b. The result's dimension is the
+// product of a's and b's dimensions, e.g. Watt*Second produces a
+// Joule-dimensioned result.
+func (a *Attribute) Mul(b *Attribute) (*Attribute, error) {
+	return a.mulDiv(b, false)
+}
+
+// Div returns a new Attribute holding a/b. The result's dimension is the
+// quotient of a's and b's dimensions, e.g. Byte/Second produces a byte-rate.
+// Dividing by a zero-valued attribute returns an error rather than the
+// panic math/big's Rat.Quo and Float.Quo would raise.
+func (a *Attribute) Div(b *Attribute) (*Attribute, error) {
+	return a.mulDiv(b, true)
+}
+
+func (a *Attribute) mulDiv(b *Attribute, invert bool) (*Attribute, error) {
+	if err := a.checkNumeric(b); err != nil {
+		return nil, err
+	}
+
+	au, bu := a.unitOrScalar(), b.unitOrScalar()
+
+	var resultDim Dimension
+	var resultScale *big.Rat
+	var op string
+	if invert {
+		resultDim = au.dimension().Div(bu.dimension())
+		resultScale = new(big.Rat).Quo(au.scale(), bu.scale())
+		op = "/"
+	} else {
+		resultDim = au.dimension().Mul(bu.dimension())
+		resultScale = new(big.Rat).Mul(au.scale(), bu.scale())
+		op = "*"
+	}
+	resultUnit := combineUnits(resultDim, resultScale, joinUnitNames(a.Unit, b.Unit, op))
+	preferInt := a.Int != nil && b.Int != nil
+
+	if ar, aok := a.getBigRat(); aok {
+		if br, bok := b.getBigRat(); bok {
+			if invert {
+				if br.Sign() == 0 {
+					return nil, fmt.Errorf("division by zero")
+				}
+				return newAttributeFromBaseRat(new(big.Rat).Quo(ar, br), resultUnit, preferInt), nil
+			}
+			return newAttributeFromBaseRat(new(big.Rat).Mul(ar, br), resultUnit, preferInt), nil
+		}
+	}
+
+	af, bf := a.getBigFloat(), b.getBigFloat()
+	if af == nil || bf == nil {
+		return nil, fmt.Errorf("attributes have no comparable numeric value")
+	}
+	if invert {
+		if bf.Sign() == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return newAttributeFromBaseFloat(new(big.Float).SetPrec(floatPrecision).Quo(af, bf), resultUnit), nil
+	}
+	return newAttributeFromBaseFloat(new(big.Float).SetPrec(floatPrecision).Mul(af, bf), resultUnit), nil
+}
+
+// Neg returns a copy of the attribute with its numeric value negated. The
+// unit, if any, is preserved.
+func (a *Attribute) Neg() (*Attribute, error) {
+	if a == nil {
+		return nil, fmt.Errorf("cannot operate on a nil attribute")
+	}
+
+	na := a.Copy()
+	switch {
+	case na.Int != nil:
+		*na.Int = -*na.Int
+	case na.Float != nil:
+		*na.Float = -*na.Float
+	default:
+		return nil, fmt.Errorf("attribute must be numeric")
+	}
+	return na, nil
+}
+
+// Abs returns a copy of the attribute with the absolute value of its numeric
+// value. The unit, if any, is preserved.
+func (a *Attribute) Abs() (*Attribute, error) {
+	if a == nil {
+		return nil, fmt.Errorf("cannot operate on a nil attribute")
+	}
+
+	na := a.Copy()
+	switch {
+	case na.Int != nil:
+		if *na.Int < 0 {
+			*na.Int = -*na.Int
+		}
+	case na.Float != nil:
+		*na.Float = math.Abs(*na.Float)
+	default:
+		return nil, fmt.Errorf("attribute must be numeric")
+	}
+	return na, nil
+}
+
+// checkNumeric returns an error unless both a and b hold an Int or Float
+// value.
+func (a *Attribute) checkNumeric(b *Attribute) error {
+	if a == nil || b == nil {
+		return fmt.Errorf("cannot operate on a nil attribute")
+	}
+	if a.Int == nil && a.Float == nil {
+		return fmt.Errorf("attribute must be numeric")
+	}
+	if b.Int == nil && b.Float == nil {
+		return fmt.Errorf("attribute must be numeric")
+	}
+	return nil
+}
+
+// unitOrScalar returns the attribute's Unit, or a dimensionless, unscaled
+// Unit when none is set, so multiplication/division always has a concrete
+// Dimension and scale to combine.
+func (a *Attribute) unitOrScalar() *Unit {
+	if u := a.getTypedUnit(); u != nil {
+		return u
+	}
+	return &Unit{Dim: Dimension{}, Prefix: big.NewRat(1, 1)}
+}
+
+// coarserUnit returns whichever of two unit names has the larger scale, so
+// Add/Sub render their result in the less precise but more human-scaled of
+// the two input units.
+func coarserUnit(a, b string) string {
+	if a == "" {
+		return b
+	}
+	if b == "" {
+		return a
+	}
+
+	au, aerr := parseUnit(a)
+	bu, berr := parseUnit(b)
+	if aerr != nil {
+		return b
+	}
+	if berr != nil {
+		return a
+	}
+	if au.scale().Cmp(bu.scale()) >= 0 {
+		return a
+	}
+	return b
+}
+
+// joinUnitNames builds a fallback composite unit name for Mul/Div results
+// that don't match a pre-registered unit, e.g. "Byte" and "Second" joined
+// with "/" produce "Byte/Second".
+func joinUnitNames(a, b, op string) string {
+	switch {
+	case a == "" && b == "":
+		return ""
+	case a == "":
+		return b
+	case b == "":
+		return a
+	default:
+		return a + op + b
+	}
+}
+
+// combineUnits returns the name of a pre-registered unit matching the given
+// Dimension and scale exactly (e.g. Watt*Second resolving to "Joule"),
+// falling back to the supplied composite name when no such unit exists.
+//
+// A dimensionless result (e.g. multiplying two plain unitless numbers, or
+// dividing an attribute by another of the same unit so it cancels out)
+// always returns "", ignoring fallback entirely: fallback is a literal join
+// of the input unit names (e.g. "GiB/GiB") that no longer describes the
+// cancelled-out result, and there's no principled way to instead pick among
+// the registry's unrelated dimensionless units (e.g. "%", "core") to
+// relabel it with. Guessing either way silently stamps a wrong unit onto
+// what is now a bare number.
+func combineUnits(dim Dimension, scale *big.Rat, fallback string) string {
+	if len(dim) == 0 {
+		return ""
+	}
+
+	match := fallback
+	found := false
+	forEachUnit(func(name string, u *Unit) {
+		if !found && u.dimension().Equal(dim) && u.scale().Cmp(scale) == 0 {
+			match = name
+			found = true
+		}
+	})
+	return match
+}
+
+// newAttributeFromBaseRat converts a value expressed in base-dimension units
+// back into unit's representation, preferring an Int result when both
+// operands were Int and the conversion is exact.
+func newAttributeFromBaseRat(base *big.Rat, unit string, preferInt bool) *Attribute {
+	value := base
+	if unit != "" {
+		if u, err := parseUnit(unit); err == nil {
+			value = new(big.Rat).Quo(base, u.scale())
+		}
+	}
+
+	if preferInt && value.IsInt() {
+		return &Attribute{Int: helper.Int64ToPtr(value.Num().Int64()), Unit: unit}
+	}
+
+	f, _ := new(big.Float).SetPrec(floatPrecision).SetRat(value).Float64()
+	return &Attribute{Float: helper.Float64ToPtr(f), Unit: unit}
+}
+
+// newAttributeFromBaseFloat converts a value expressed in base-dimension
+// units back into unit's representation.
+func newAttributeFromBaseFloat(base *big.Float, unit string) *Attribute {
+	value := base
+	if unit != "" {
+		if u, err := parseUnit(unit); err == nil {
+			scale := new(big.Float).SetPrec(floatPrecision).SetRat(u.scale())
+			value = new(big.Float).SetPrec(floatPrecision).Quo(base, scale)
+		}
+	}
+
+	f, _ := value.Float64()
+	return &Attribute{Float: helper.Float64ToPtr(f), Unit: unit}
+}
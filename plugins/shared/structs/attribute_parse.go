@@ -0,0 +1,306 @@
+package structs
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/hashicorp/nomad/helper"
+)
+
+// AttributeParser attempts to parse input into an Attribute. ok is false
+// when input doesn't match the format the parser looks for, letting
+// ParseAttribute fall through to the next parser in the chain; a non-nil err
+// means input matched the format but was malformed and parsing should stop.
+type AttributeParser func(input string) (attr *Attribute, ok bool, err error)
+
+type namedAttributeParser struct {
+	name string
+	fn   AttributeParser
+}
+
+// attributeParsersMu guards attributeParsers. RegisterAttributeParser is
+// designed to be called by plugins at any time, not just during init, while
+// ParseAttribute is read from arbitrary goroutines (scheduler workers,
+// fingerprinters), so the chain needs synchronization beyond init-time
+// population.
+var attributeParsersMu sync.RWMutex
+
+// attributeParsers is consulted by ParseAttribute in order, so more specific
+// formats (a duration, a percentage) must be registered before the generic
+// unit-suffixed number and plain number parsers they'd otherwise be
+// swallowed by. Access must hold attributeParsersMu.
+var attributeParsers []namedAttributeParser
+
+// RegisterAttributeParser adds fn to the chain ParseAttribute consults, so
+// operators can teach Nomad about domain-specific value formats (a custom
+// timestamp format, an identifier scheme, ...) without patching
+// ParseAttribute itself. name must be unique and is used only for error
+// messages and later lookup by RegisterAttributeParser. Safe to call
+// concurrently with ParseAttribute and with other RegisterAttributeParser
+// calls.
+func RegisterAttributeParser(name string, fn AttributeParser) error {
+	if name == "" {
+		return fmt.Errorf("attribute parser name may not be empty")
+	}
+
+	attributeParsersMu.Lock()
+	defer attributeParsersMu.Unlock()
+
+	for _, p := range attributeParsers {
+		if p.name == name {
+			return fmt.Errorf("attribute parser %q is already registered", name)
+		}
+	}
+
+	attributeParsers = append(attributeParsers, namedAttributeParser{name, fn})
+	return nil
+}
+
+func init() {
+	// Order matters: each parser only needs to reject inputs it doesn't
+	// recognize, and more specific formats run before fallbacks that would
+	// otherwise accept them too. quoted-string must run first so a String
+	// attribute whose value looks like a bool/int/float/unit (as produced by
+	// Canonical) isn't reclassified by a later parser's heuristics.
+	RegisterAttributeParser("quoted-string", parseQuotedStringAttribute)
+	RegisterAttributeParser("bool", parseBoolAttribute)
+	RegisterAttributeParser("hex", parseIntLiteralAttribute)
+	RegisterAttributeParser("duration", parseDurationAttribute)
+	RegisterAttributeParser("percent", parsePercentAttribute)
+	RegisterAttributeParser("unit", parseUnitSuffixedAttribute)
+	RegisterAttributeParser("number", parseNumberAttribute)
+}
+
+// ParseAttribute takes a string and parses it into an Attribute, trying each
+// registered AttributeParser in turn and falling back to a plain string
+// attribute if none recognize the input.
+func ParseAttribute(input string) *Attribute {
+	if len(input) == 0 {
+		return &Attribute{String: helper.StringToPtr(input)}
+	}
+
+	attributeParsersMu.RLock()
+	parsers := make([]namedAttributeParser, len(attributeParsers))
+	copy(parsers, attributeParsers)
+	attributeParsersMu.RUnlock()
+
+	for _, p := range parsers {
+		attr, ok, err := p.fn(input)
+		if err != nil {
+			break
+		}
+		if ok {
+			return attr
+		}
+	}
+
+	return &Attribute{String: helper.StringToPtr(input)}
+}
+
+// parseQuotedStringAttribute recognizes a Go-quoted string ("\"true\"") as
+// produced by Canonical for a String attribute. This is the discriminator
+// that lets a String value round-trip through Canonical/MarshalJSON even
+// when its contents look like another type, e.g. "true" or "42".
+func parseQuotedStringAttribute(input string) (*Attribute, bool, error) {
+	if len(input) < 2 || input[0] != '"' {
+		return nil, false, nil
+	}
+
+	s, err := strconv.Unquote(input)
+	if err != nil {
+		return nil, false, nil
+	}
+
+	return &Attribute{String: helper.StringToPtr(s)}, true, nil
+}
+
+// parseBoolAttribute recognizes "true"/"false" and friends (see
+// strconv.ParseBool).
+func parseBoolAttribute(input string) (*Attribute, bool, error) {
+	b, err := strconv.ParseBool(input)
+	if err != nil {
+		return nil, false, nil
+	}
+
+	return &Attribute{Bool: helper.BoolToPtr(b)}, true, nil
+}
+
+// parseIntLiteralAttribute recognizes hex ("0xff") and binary ("0b1010")
+// integer literals.
+func parseIntLiteralAttribute(input string) (*Attribute, bool, error) {
+	lower := strings.ToLower(input)
+	if !strings.HasPrefix(lower, "0x") && !strings.HasPrefix(lower, "0b") {
+		return nil, false, nil
+	}
+
+	i, err := strconv.ParseInt(input, 0, 64)
+	if err != nil {
+		return nil, false, nil
+	}
+
+	return &Attribute{Int: helper.Int64ToPtr(i)}, true, nil
+}
+
+// parseDurationAttribute recognizes a Go duration string ("30m", "1h30m")
+// and stores it as an integer number of nanoseconds, the one unit every
+// duration converts into exactly.
+func parseDurationAttribute(input string) (*Attribute, bool, error) {
+	if !strings.ContainsFunc(input, unicode.IsLetter) {
+		return nil, false, nil
+	}
+
+	d, err := time.ParseDuration(input)
+	if err != nil {
+		return nil, false, nil
+	}
+
+	return &Attribute{Int: helper.Int64ToPtr(d.Nanoseconds()), Unit: "ns"}, true, nil
+}
+
+// parsePercentAttribute recognizes a trailing "%" and stores the value as a
+// dimensionless ratio: "95%" becomes 95 with unit "%", whose registered
+// Prefix of 1/100 makes it comparable to any other dimensionless attribute.
+func parsePercentAttribute(input string) (*Attribute, bool, error) {
+	numeric, ok := strings.CutSuffix(input, "%")
+	if !ok {
+		return nil, false, nil
+	}
+
+	if i, err := strconv.ParseInt(numeric, 10, 64); err == nil {
+		return &Attribute{Int: helper.Int64ToPtr(i), Unit: "%"}, true, nil
+	}
+	if f, err := strconv.ParseFloat(numeric, 64); err == nil {
+		return &Attribute{Float: helper.Float64ToPtr(f), Unit: "%"}, true, nil
+	}
+
+	return nil, false, nil
+}
+
+// parseUnitSuffixedAttribute recognizes a number followed by a unit
+// expression, simple ("GiB") or composite ("MB/s", "core·GHz", and anything
+// else parseUnit understands), by trimming unit characters off the end of
+// input until what remains parses as a number.
+func parseUnitSuffixedAttribute(input string) (*Attribute, bool, error) {
+	ll := len(input)
+	if !unicode.IsLetter(rune(input[ll-1])) {
+		return nil, false, nil
+	}
+
+	end := ll
+	for end > 0 && isUnitChar(rune(input[end-1])) {
+		end--
+	}
+	numeric := strings.TrimSpace(input[:end])
+	unit := strings.TrimSpace(input[end:])
+	if numeric == "" || unit == "" {
+		return nil, false, nil
+	}
+
+	if _, err := parseUnit(unit); err != nil {
+		return nil, false, nil
+	}
+
+	if i, err := strconv.ParseInt(numeric, 10, 64); err == nil {
+		return &Attribute{Int: helper.Int64ToPtr(i), Unit: unit}, true, nil
+	}
+	if f, err := strconv.ParseFloat(numeric, 64); err == nil {
+		return &Attribute{Float: helper.Float64ToPtr(f), Unit: unit}, true, nil
+	}
+
+	return nil, false, nil
+}
+
+// isUnitChar reports whether r can appear inside a unit expression, as
+// opposed to the numeric value it's attached to (including the scientific
+// notation exponent marker, which parseUnitSuffixedAttribute never reaches
+// because it always borders a digit).
+func isUnitChar(r rune) bool {
+	return unicode.IsLetter(r) || r == '/' || r == '*' || r == '·' || r == '%'
+}
+
+// parseNumberAttribute recognizes a plain int or float literal, including
+// scientific notation, with no unit.
+func parseNumberAttribute(input string) (*Attribute, bool, error) {
+	if i, err := strconv.ParseInt(input, 10, 64); err == nil {
+		return &Attribute{Int: helper.Int64ToPtr(i)}, true, nil
+	}
+	if f, err := strconv.ParseFloat(input, 64); err == nil {
+		return &Attribute{Float: helper.Float64ToPtr(f)}, true, nil
+	}
+
+	return nil, false, nil
+}
+
+// Canonical returns the canonical textual form of the attribute, the inverse
+// of ParseAttribute: ParseAttribute(a.Canonical()) always reproduces an
+// equivalent Attribute. It is named Canonical rather than String because the
+// attribute's String field already claims that method name for fmt.Stringer.
+// MarshalJSON uses this form so fingerprints round-trip through the API and
+// event stream without losing their unit.
+//
+// A String value is quoted (strconv.Quote) rather than written bare: without
+// a discriminator a String attribute holding "true" or "42" would come back
+// from ParseAttribute as Bool/Int instead, silently changing its type.
+func (a *Attribute) Canonical() string {
+	if a == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	switch {
+	case a.Bool != nil:
+		b.WriteString(strconv.FormatBool(*a.Bool))
+	case a.Int != nil:
+		// A bare "0" or "1" is read back as a Bool by parseBoolAttribute, so
+		// those two values get an explicit sign.
+		if a.Unit == "" && (*a.Int == 0 || *a.Int == 1) {
+			b.WriteByte('+')
+		}
+		b.WriteString(strconv.FormatInt(*a.Int, 10))
+	case a.Float != nil:
+		// An integral float such as 1 or 5 gets a ".0" so it is not read back
+		// as a Bool or an Int.
+		s := strconv.FormatFloat(*a.Float, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".eIN") {
+			s += ".0"
+		}
+		b.WriteString(s)
+	case a.String != nil:
+		return strconv.Quote(*a.String)
+	default:
+		return ""
+	}
+
+	if a.Unit != "" {
+		b.WriteString(a.Unit)
+	}
+
+	return b.String()
+}
+
+// MarshalJSON encodes the attribute as its Canonical string rather than as
+// an object of its individual fields.
+func (a *Attribute) MarshalJSON() ([]byte, error) {
+	if a == nil {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(a.Canonical())
+}
+
+// UnmarshalJSON decodes an attribute from its Canonical string form via
+// ParseAttribute.
+func (a *Attribute) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	*a = *ParseAttribute(s)
+	return nil
+}
@@ -0,0 +1,245 @@
+package structs
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/helper"
+)
+
+// TestAttribute_Comparable_CrossType ensures differently-typed, unit-less
+// attributes are reported incomparable rather than panicking when Compare
+// dereferences the wrong field. See getTypedUnit's doc comment: parseUnit("")
+// must not make every unit-less pair look comparable.
+func TestAttribute_Comparable_CrossType(t *testing.T) {
+	cases := []struct {
+		name string
+		a, b *Attribute
+	}{
+		{
+			name: "string vs bool",
+			a:    &Attribute{String: helper.StringToPtr("x")},
+			b:    &Attribute{Bool: helper.BoolToPtr(true)},
+		},
+		{
+			name: "bool vs int",
+			a:    &Attribute{Bool: helper.BoolToPtr(true)},
+			b:    &Attribute{Int: helper.Int64ToPtr(1)},
+		},
+		{
+			name: "string vs int",
+			a:    &Attribute{String: helper.StringToPtr("1")},
+			b:    &Attribute{Int: helper.Int64ToPtr(1)},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.a.Comparable(c.b) {
+				t.Fatalf("expected %v and %v to be incomparable", c.a, c.b)
+			}
+
+			n, ok := c.a.Compare(c.b)
+			if ok {
+				t.Fatalf("expected Compare to report ok=false, got (%d, %v)", n, ok)
+			}
+			if n != 0 {
+				t.Fatalf("expected Compare to return 0 when incomparable, got %d", n)
+			}
+		})
+	}
+}
+
+// TestAttribute_Compare_ExactFractionalUnits verifies Compare uses exact
+// big.Rat arithmetic for Int-valued attributes with a fractional unit
+// multiplier (mW is W/1000), where big.Float's fixed precision could
+// otherwise report a spurious inequality.
+func TestAttribute_Compare_ExactFractionalUnits(t *testing.T) {
+	thousandMW := &Attribute{Int: helper.Int64ToPtr(1000), Unit: "mW"}
+	oneW := &Attribute{Int: helper.Int64ToPtr(1), Unit: "W"}
+
+	n, ok := thousandMW.Compare(oneW)
+	if !ok {
+		t.Fatalf("expected 1000mW and 1W to be comparable")
+	}
+	if n != 0 {
+		t.Fatalf("expected 1000mW == 1W, got Compare = %d", n)
+	}
+
+	threeThirtyThreeMW := &Attribute{Int: helper.Int64ToPtr(333), Unit: "mW"}
+	n, ok = threeThirtyThreeMW.Compare(oneW)
+	if !ok {
+		t.Fatalf("expected 333mW and 1W to be comparable")
+	}
+	if n != -1 {
+		t.Fatalf("expected 333mW < 1W, got Compare = %d", n)
+	}
+}
+
+// TestAttribute_Div_ByZero ensures dividing by a zero-valued attribute
+// returns an error instead of panicking the way math/big's Rat.Quo and
+// Float.Quo would.
+func TestAttribute_Div_ByZero(t *testing.T) {
+	ten := &Attribute{Int: helper.Int64ToPtr(10)}
+	zero := &Attribute{Int: helper.Int64ToPtr(0)}
+
+	if _, err := ten.Div(zero); err == nil {
+		t.Fatalf("expected an error dividing by a zero Int attribute")
+	}
+
+	zeroFloat := &Attribute{Float: helper.Float64ToPtr(0)}
+	if _, err := ten.Div(zeroFloat); err == nil {
+		t.Fatalf("expected an error dividing by a zero Float attribute")
+	}
+}
+
+// TestAttribute_Add_DimensionMismatch ensures Add rejects operands whose
+// units describe different dimensions.
+func TestAttribute_Add_DimensionMismatch(t *testing.T) {
+	bytes := &Attribute{Int: helper.Int64ToPtr(1), Unit: "GiB"}
+	hz := &Attribute{Int: helper.Int64ToPtr(1), Unit: "GHz"}
+
+	if _, err := bytes.Add(hz); err == nil {
+		t.Fatalf("expected an error adding incompatible units")
+	}
+}
+
+// TestAttribute_Mul_Unitless ensures multiplying two plain, unitless
+// attributes produces a bare, unitless result rather than being mislabeled
+// with an unrelated pre-registered unit that happens to share Dimension{}.
+func TestAttribute_Mul_Unitless(t *testing.T) {
+	five := &Attribute{Int: helper.Int64ToPtr(5)}
+	three := &Attribute{Int: helper.Int64ToPtr(3)}
+
+	r, err := five.Mul(three)
+	if err != nil {
+		t.Fatalf("Mul: %v", err)
+	}
+	if r.Unit != "" {
+		t.Fatalf("expected unitless result, got unit %q", r.Unit)
+	}
+	if r.Int == nil || *r.Int != 15 {
+		t.Fatalf("expected 15, got %#v", r)
+	}
+}
+
+// TestAttribute_Div_SameUnitCancels ensures dividing two attributes sharing
+// a unit produces a bare, unitless ratio rather than being mislabeled with
+// an unrelated unit or the literal "unit/unit" join.
+func TestAttribute_Div_SameUnitCancels(t *testing.T) {
+	twoGiB := &Attribute{Int: helper.Int64ToPtr(2), Unit: "GiB"}
+	oneGiB := &Attribute{Int: helper.Int64ToPtr(1), Unit: "GiB"}
+
+	r, err := twoGiB.Div(oneGiB)
+	if err != nil {
+		t.Fatalf("Div: %v", err)
+	}
+	if r.Unit != "" {
+		t.Fatalf("expected unitless result, got unit %q", r.Unit)
+	}
+	if r.Int == nil || *r.Int != 2 {
+		t.Fatalf("expected 2, got %#v", r)
+	}
+}
+
+// TestAttribute_Mul_CorePreservesDimension ensures multiplying a core count
+// by a frequency keeps both factors in the result's unit instead of core
+// cancelling to nothing and leaving a plain frequency, per the request's
+// cpu.totalcompute = cores * frequency example.
+func TestAttribute_Mul_CorePreservesDimension(t *testing.T) {
+	fourCore := &Attribute{Int: helper.Int64ToPtr(4), Unit: "core"}
+	threeGHz := &Attribute{Int: helper.Int64ToPtr(3), Unit: "GHz"}
+
+	r, err := fourCore.Mul(threeGHz)
+	if err != nil {
+		t.Fatalf("Mul: %v", err)
+	}
+	if r.Int == nil || *r.Int != 12 {
+		t.Fatalf("expected value 12, got %#v", r)
+	}
+	if !strings.Contains(r.Unit, "core") || !strings.Contains(r.Unit, "GHz") {
+		t.Fatalf("expected result unit to retain both core and GHz, got %q", r.Unit)
+	}
+}
+
+// TestAttribute_Compare_DimensionlessUnitVsBareNumber verifies a
+// dimensionless unit (e.g. "%") is comparable against a bare, unitless
+// number, per parsePercentAttribute's doc comment.
+func TestAttribute_Compare_DimensionlessUnitVsBareNumber(t *testing.T) {
+	pct := ParseAttribute("95%")
+	bare := ParseAttribute("95")
+
+	if !pct.Comparable(bare) {
+		t.Fatalf("expected 95%% to be comparable to a bare 95")
+	}
+	if n, ok := pct.Compare(bare); !ok || n != -1 {
+		t.Fatalf("expected 95%% < 95, got (%d, %v)", n, ok)
+	}
+
+	half := ParseAttribute("50%")
+	asRatio := ParseAttribute("0.5") // exactly representable in binary, unlike 0.95
+	if n, ok := half.Compare(asRatio); !ok || n != 0 {
+		t.Fatalf("expected 50%% == 0.5, got (%d, %v)", n, ok)
+	}
+}
+
+// TestAttribute_JSON_RoundTrip verifies MarshalJSON/UnmarshalJSON round-trip
+// every attribute type, including adversarial strings that look like other
+// types and would otherwise be silently reclassified by ParseAttribute's
+// heuristics.
+func TestAttribute_JSON_RoundTrip(t *testing.T) {
+	cases := []struct {
+		name string
+		in   *Attribute
+	}{
+		{"bool true", &Attribute{Bool: helper.BoolToPtr(true)}},
+		{"bool false", &Attribute{Bool: helper.BoolToPtr(false)}},
+		{"int", &Attribute{Int: helper.Int64ToPtr(42)}},
+		{"int with unit", &Attribute{Int: helper.Int64ToPtr(5), Unit: "GiB"}},
+		{"float", &Attribute{Float: helper.Float64ToPtr(3.14)}},
+		{"string", &Attribute{String: helper.StringToPtr("hello-world")}},
+		{"string looks like bool", &Attribute{String: helper.StringToPtr("true")}},
+		{"string looks like int", &Attribute{String: helper.StringToPtr("42")}},
+		{"string looks like float", &Attribute{String: helper.StringToPtr("3.14")}},
+		{"string looks like hex", &Attribute{String: helper.StringToPtr("0xff")}},
+		{"string looks like unit", &Attribute{String: helper.StringToPtr("5GiB")}},
+		{"empty string", &Attribute{String: helper.StringToPtr("")}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data, err := json.Marshal(c.in)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+
+			var out Attribute
+			if err := json.Unmarshal(data, &out); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+
+			switch {
+			case c.in.Bool != nil:
+				if out.Bool == nil || *out.Bool != *c.in.Bool {
+					t.Fatalf("Bool mismatch: got %#v, want %#v", out, c.in)
+				}
+			case c.in.Int != nil:
+				if out.Int == nil || *out.Int != *c.in.Int || out.Unit != c.in.Unit {
+					t.Fatalf("Int mismatch: got %#v, want %#v", out, c.in)
+				}
+			case c.in.Float != nil:
+				if out.Float == nil || *out.Float != *c.in.Float {
+					t.Fatalf("Float mismatch: got %#v, want %#v", out, c.in)
+				}
+			case c.in.String != nil:
+				if out.String == nil || *out.String != *c.in.String {
+					t.Fatalf("String mismatch: got %#v, want %#v", out, c.in)
+				}
+				if out.Bool != nil || out.Int != nil || out.Float != nil {
+					t.Fatalf("expected only String set, got %#v", out)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package structs
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// BaseDimension is one of the fundamental physical quantities that composite
+// units are built out of.
+type BaseDimension uint8
+
+const (
+	DimByte BaseDimension = iota
+	DimTime
+	DimEnergy
+	DimCore
+)
+
+// Dimension describes a unit in terms of the exponents of the base
+// dimensions it is composed of. For example a byte-rate such as MB/s is
+// {DimByte: 1, DimTime: -1}. A Dimension with no entries is dimensionless
+// (a scalar such as a percentage or a core count).
+type Dimension map[BaseDimension]int
+
+// Equal returns whether two dimensions describe the same quantity. Entries
+// with a zero exponent are ignored so a Dimension built up through
+// cancelling multiplication/division compares equal to one that never held
+// the cancelled dimension at all.
+func (d Dimension) Equal(o Dimension) bool {
+	for k, v := range d {
+		if v != 0 && o[k] != v {
+			return false
+		}
+	}
+	for k, v := range o {
+		if v != 0 && d[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Mul returns the dimension produced by multiplying a quantity of dimension
+// d with one of dimension o.
+func (d Dimension) Mul(o Dimension) Dimension {
+	return combineDimensions(d, o, 1)
+}
+
+// Div returns the dimension produced by dividing a quantity of dimension d
+// by one of dimension o.
+func (d Dimension) Div(o Dimension) Dimension {
+	return combineDimensions(d, o, -1)
+}
+
+func combineDimensions(d, o Dimension, sign int) Dimension {
+	out := make(Dimension, len(d)+len(o))
+	for k, v := range d {
+		out[k] += v
+	}
+	for k, v := range o {
+		out[k] += sign * v
+	}
+	for k, v := range out {
+		if v == 0 {
+			delete(out, k)
+		}
+	}
+	return out
+}
+
+// scale returns the unit's multiplier relative to the SI base of its
+// Dimension, preferring the exact Prefix when set and otherwise deriving it
+// from the legacy Multiplier/InverseMultiplier pair.
+func (u *Unit) scale() *big.Rat {
+	if u.Prefix != nil {
+		return u.Prefix
+	}
+	if u.Multiplier == 0 {
+		return big.NewRat(1, 1)
+	}
+	if u.InverseMultiplier {
+		return big.NewRat(1, u.Multiplier)
+	}
+	return big.NewRat(u.Multiplier, 1)
+}
+
+// dimension returns the Dimension of the unit, deriving it from the legacy
+// Base field when Dim is unset so the handful of units registered before
+// Dimension existed keep comparing correctly.
+func (u *Unit) dimension() Dimension {
+	if u.Dim != nil {
+		return u.Dim
+	}
+
+	switch u.Base {
+	case UnitByte:
+		return Dimension{DimByte: 1}
+	case UnitByteRate:
+		return Dimension{DimByte: 1, DimTime: -1}
+	case UnitHertz:
+		return Dimension{DimTime: -1}
+	case UnitWatt:
+		return Dimension{DimEnergy: 1, DimTime: -1}
+	default:
+		return Dimension{}
+	}
+}
+
+// unitRegistryMu guards unitRegistry and lengthSortedUnits. RegisterUnit is
+// designed to be called by plugins at any time, not just during init, and
+// parseUnit is read from scheduler workers and fingerprinters running on
+// arbitrary goroutines, so the registry cannot rely on init-time population
+// alone the way the original UnitIndex did.
+var unitRegistryMu sync.RWMutex
+
+// unitRegistry holds every unit known to Nomad, keyed by its textual name
+// (GiB, MB/s, ...). It is seeded with the built-in units below and grown at
+// runtime by RegisterUnit. Access must hold unitRegistryMu.
+var unitRegistry = map[string]*Unit{}
+
+// UnitIndex is the set of all known units, indexed by name. Kept as an alias
+// of unitRegistry for code that looks up a Unit directly rather than through
+// an Attribute. Unlike parseUnit/RegisterUnit, indexing into UnitIndex
+// directly isn't synchronized against concurrent RegisterUnit calls; prefer
+// parseUnit when a RegisterUnit call might be racing.
+var UnitIndex = unitRegistry
+
+// lengthSortedUnits contains the keys of unitRegistry sorted from longest to
+// shortest. ParseAttribute does not consult it; unit suffixes are resolved
+// through parseUnit. Access must hold unitRegistryMu.
+var lengthSortedUnits []string
+
+func init() {
+	unitRegistryMu.Lock()
+	defer unitRegistryMu.Unlock()
+
+	register := func(u *Unit) {
+		unitRegistry[u.Name] = u
+	}
+
+	register(&Unit{Name: "B", Base: UnitByte, Multiplier: 1})
+	register(&Unit{Name: "KB", Base: UnitByte, Multiplier: 1000})
+	register(&Unit{Name: "MB", Base: UnitByte, Multiplier: 1000 * 1000})
+	register(&Unit{Name: "GB", Base: UnitByte, Multiplier: 1000 * 1000 * 1000})
+	register(&Unit{Name: "TB", Base: UnitByte, Multiplier: 1000 * 1000 * 1000 * 1000})
+	register(&Unit{Name: "KiB", Base: UnitByte, Multiplier: 1024})
+	register(&Unit{Name: "MiB", Base: UnitByte, Multiplier: 1024 * 1024})
+	register(&Unit{Name: "GiB", Base: UnitByte, Multiplier: 1024 * 1024 * 1024})
+	register(&Unit{Name: "TiB", Base: UnitByte, Multiplier: 1024 * 1024 * 1024 * 1024})
+
+	register(&Unit{Name: "Hz", Base: UnitHertz, Multiplier: 1})
+	register(&Unit{Name: "KHz", Base: UnitHertz, Multiplier: 1000})
+	register(&Unit{Name: "MHz", Base: UnitHertz, Multiplier: 1000 * 1000})
+	register(&Unit{Name: "GHz", Base: UnitHertz, Multiplier: 1000 * 1000 * 1000})
+
+	register(&Unit{Name: "W", Base: UnitWatt, Multiplier: 1})
+	register(&Unit{Name: "mW", Base: UnitWatt, Multiplier: 1000, InverseMultiplier: true})
+	register(&Unit{Name: "KW", Base: UnitWatt, Multiplier: 1000})
+
+	register(&Unit{Name: "MB/s", Base: UnitByteRate, Multiplier: 1000 * 1000})
+	register(&Unit{Name: "GB/s", Base: UnitByteRate, Multiplier: 1000 * 1000 * 1000})
+	register(&Unit{Name: "MiB/s", Base: UnitByteRate, Multiplier: 1024 * 1024})
+	register(&Unit{Name: "GiB/s", Base: UnitByteRate, Multiplier: 1024 * 1024 * 1024})
+
+	// s and core are atomic factors used to build up composite units (e.g.
+	// "MB/s" or "core·GHz") that aren't pre-registered above. core gets its
+	// own dimension slot rather than Dimension{}: it must stay distinguishable
+	// so multiplying a core count by a frequency produces a total-compute
+	// quantity instead of cancelling to a plain frequency.
+	register(&Unit{Name: "s", Dim: Dimension{DimTime: 1}, Prefix: big.NewRat(1, 1)})
+	register(&Unit{Name: "core", Dim: Dimension{DimCore: 1}, Prefix: big.NewRat(1, 1)})
+
+	// Joule is the SI unit of energy, dimensionally Watt*Second. It lets
+	// Attribute.Mul recognize a Watt-by-Second product instead of leaving it
+	// as the synthetic name "W*s".
+	register(&Unit{Name: "Joule", Dim: Dimension{DimEnergy: 1}, Prefix: big.NewRat(1, 1)})
+
+	// ns backs parseDurationAttribute's canonical nanosecond representation
+	// of any Go duration string ("30m", "1h30m", ...).
+	register(&Unit{Name: "ns", Dim: Dimension{DimTime: 1}, Prefix: big.NewRat(1, 1000000000)})
+
+	// % is a dimensionless ratio, comparable to any other unitless
+	// attribute, used by parsePercentAttribute.
+	register(&Unit{Name: "%", Dim: Dimension{}, Prefix: big.NewRat(1, 100)})
+
+	resortUnitsLocked()
+}
+
+// resortUnitsLocked rebuilds lengthSortedUnits from unitRegistry. Callers
+// must hold unitRegistryMu for writing.
+func resortUnitsLocked() {
+	lengthSortedUnits = lengthSortedUnits[:0]
+	for name := range unitRegistry {
+		lengthSortedUnits = append(lengthSortedUnits, name)
+	}
+	sort.Slice(lengthSortedUnits, func(i, j int) bool {
+		return len(lengthSortedUnits[i]) > len(lengthSortedUnits[j])
+	})
+}
+
+// RegisterUnit teaches Nomad about a new atomic unit with the given
+// dimension and scale relative to the SI base of that dimension, e.g.
+//
+//	RegisterUnit("IOPS", Dimension{DimTime: -1}, big.NewRat(1, 1))
+//
+// Composite units built from it, such as "IOPS/core", are then resolved
+// automatically by getTypedUnit. It is an error to register a name that
+// already exists. Safe to call concurrently with parseUnit and with other
+// RegisterUnit calls.
+func RegisterUnit(name string, dim Dimension, scale *big.Rat) error {
+	if name == "" {
+		return fmt.Errorf("unit name may not be empty")
+	}
+
+	unitRegistryMu.Lock()
+	defer unitRegistryMu.Unlock()
+
+	if _, ok := unitRegistry[name]; ok {
+		return fmt.Errorf("unit %q is already registered", name)
+	}
+
+	unitRegistry[name] = &Unit{Name: name, Dim: dim, Prefix: scale}
+	resortUnitsLocked()
+	return nil
+}
+
+// lookupUnit returns the registered unit named name, if any.
+func lookupUnit(name string) (*Unit, bool) {
+	unitRegistryMu.RLock()
+	defer unitRegistryMu.RUnlock()
+
+	u, ok := unitRegistry[name]
+	return u, ok
+}
+
+// parseUnit resolves a unit expression such as "GiB", "MB/s" or "core·GHz"
+// into a synthetic Unit describing its combined Dimension and scale.
+// Division is expressed with '/' and multiplication with '*' or '·'; neither
+// operator nests, matching the unit expressions node fingerprinters emit.
+func parseUnit(expr string) (*Unit, error) {
+	if expr == "" {
+		return &Unit{Dim: Dimension{}, Prefix: big.NewRat(1, 1)}, nil
+	}
+
+	// Most units in use are already registered verbatim (GiB, MB/s); only
+	// fall back to decomposing the expression when that lookup misses.
+	if u, ok := lookupUnit(expr); ok {
+		return u, nil
+	}
+
+	num, den, hasQuotient := strings.Cut(expr, "/")
+
+	numUnit, err := parseUnitProduct(num)
+	if err != nil {
+		return nil, err
+	}
+	if !hasQuotient {
+		return numUnit, nil
+	}
+
+	denUnit, err := parseUnitProduct(den)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Unit{
+		Name:   expr,
+		Dim:    numUnit.dimension().Div(denUnit.dimension()),
+		Prefix: new(big.Rat).Quo(numUnit.scale(), denUnit.scale()),
+	}, nil
+}
+
+// parseUnitProduct resolves a '*'/'·' separated chain of unit factors, e.g.
+// "core·GHz", into their combined Dimension and scale.
+func parseUnitProduct(expr string) (*Unit, error) {
+	factors := strings.FieldsFunc(expr, func(r rune) bool {
+		return r == '*' || r == '·'
+	})
+	if len(factors) == 0 {
+		return nil, fmt.Errorf("invalid unit expression %q", expr)
+	}
+
+	out := &Unit{Name: expr, Dim: Dimension{}, Prefix: big.NewRat(1, 1)}
+	for _, f := range factors {
+		f = strings.TrimSpace(f)
+		u, ok := lookupUnit(f)
+		if !ok {
+			return nil, fmt.Errorf("unknown unit %q", f)
+		}
+		out.Dim = out.Dim.Mul(u.dimension())
+		out.Prefix = new(big.Rat).Mul(out.Prefix, u.scale())
+	}
+
+	return out, nil
+}
+
+// forEachUnit calls fn for every registered unit. Used by combineUnits to
+// find a pre-registered name matching a freshly computed Dimension/scale.
+func forEachUnit(fn func(name string, u *Unit)) {
+	unitRegistryMu.RLock()
+	defer unitRegistryMu.RUnlock()
+
+	for name, u := range unitRegistry {
+		fn(name, u)
+	}
+}
@@ -0,0 +1,114 @@
+package structs
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestDimension_Equal(t *testing.T) {
+	cases := []struct {
+		name string
+		a, b Dimension
+		want bool
+	}{
+		{"both empty", Dimension{}, Dimension{}, true},
+		{"same single dim", Dimension{DimByte: 1}, Dimension{DimByte: 1}, true},
+		{"zero exponent ignored", Dimension{DimByte: 1, DimTime: 0}, Dimension{DimByte: 1}, true},
+		{"different exponent", Dimension{DimByte: 1}, Dimension{DimByte: 2}, false},
+		{"different dim", Dimension{DimByte: 1}, Dimension{DimTime: 1}, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.a.Equal(c.b); got != c.want {
+				t.Fatalf("%v.Equal(%v) = %v, want %v", c.a, c.b, got, c.want)
+			}
+		})
+	}
+}
+
+func TestDimension_MulDiv(t *testing.T) {
+	byteRate := Dimension{DimByte: 1}.Div(Dimension{DimTime: 1})
+	if !byteRate.Equal(Dimension{DimByte: 1, DimTime: -1}) {
+		t.Fatalf("Byte/Time = %v, want {Byte:1, Time:-1}", byteRate)
+	}
+
+	// Multiplying a byte-rate back by Time should cancel DimTime entirely.
+	back := byteRate.Mul(Dimension{DimTime: 1})
+	if !back.Equal(Dimension{DimByte: 1}) {
+		t.Fatalf("byteRate*Time = %v, want {Byte:1}", back)
+	}
+}
+
+// TestUnit_CoreHasOwnDimension ensures "core" does not share the empty
+// Dimension{} of a plain dimensionless scalar: it must stay distinguishable
+// so multiplying a core count by another unit doesn't cancel out.
+func TestUnit_CoreHasOwnDimension(t *testing.T) {
+	core, err := parseUnit("core")
+	if err != nil {
+		t.Fatalf("parseUnit(core): %v", err)
+	}
+	if len(core.dimension()) == 0 {
+		t.Fatalf("expected core to have a non-empty dimension, got %v", core.dimension())
+	}
+
+	ghz, err := parseUnit("GHz")
+	if err != nil {
+		t.Fatalf("parseUnit(GHz): %v", err)
+	}
+	if core.Comparable(ghz) {
+		t.Fatalf("core and GHz should not be comparable")
+	}
+}
+
+func TestRegisterUnit_CompositeResolution(t *testing.T) {
+	if err := RegisterUnit("widget", Dimension{DimByte: 1}, big.NewRat(7, 1)); err != nil {
+		t.Fatalf("RegisterUnit: %v", err)
+	}
+
+	u, err := parseUnit("widget/s")
+	if err != nil {
+		t.Fatalf("parseUnit(widget/s): %v", err)
+	}
+	if !u.dimension().Equal(Dimension{DimByte: 1, DimTime: -1}) {
+		t.Fatalf("widget/s dimension = %v, want {Byte:1, Time:-1}", u.dimension())
+	}
+	if u.scale().Cmp(big.NewRat(7, 1)) != 0 {
+		t.Fatalf("widget/s scale = %v, want 7", u.scale())
+	}
+}
+
+func TestRegisterUnit_DuplicateRejected(t *testing.T) {
+	if err := RegisterUnit("duplicate-widget", Dimension{}, big.NewRat(1, 1)); err != nil {
+		t.Fatalf("first RegisterUnit: %v", err)
+	}
+
+	if err := RegisterUnit("duplicate-widget", Dimension{}, big.NewRat(1, 1)); err == nil {
+		t.Fatalf("expected an error registering a duplicate unit name")
+	}
+}
+
+func TestUnit_Comparable(t *testing.T) {
+	gib, err := parseUnit("GiB")
+	if err != nil {
+		t.Fatalf("parseUnit(GiB): %v", err)
+	}
+	mbPerSec, err := parseUnit("MB/s")
+	if err != nil {
+		t.Fatalf("parseUnit(MB/s): %v", err)
+	}
+	gibPerSec, err := parseUnit("GiB/s")
+	if err != nil {
+		t.Fatalf("parseUnit(GiB/s): %v", err)
+	}
+
+	if gib.Comparable(mbPerSec) {
+		t.Fatalf("GiB and MB/s should not be comparable")
+	}
+	if !mbPerSec.Comparable(gibPerSec) {
+		t.Fatalf("MB/s and GiB/s should be comparable")
+	}
+	if gib.Comparable(nil) {
+		t.Fatalf("nothing should be comparable to a nil unit")
+	}
+}